@@ -0,0 +1,121 @@
+package SocketIO
+
+import (
+	"context"
+	"time"
+)
+
+// AsyncOverflowPolicy controls what happens when the async publisher's
+// buffer is full and a new packet arrives.
+type AsyncOverflowPolicy string
+
+const (
+	// AsyncOverflowBlock makes Emit block until buffer space frees up.
+	AsyncOverflowBlock AsyncOverflowPolicy = "block"
+	// AsyncOverflowDropOldest discards the oldest buffered packet to
+	// make room for the new one, favoring freshness over completeness.
+	AsyncOverflowDropOldest AsyncOverflowPolicy = "drop-oldest"
+)
+
+// asyncPublisher coalesces packets handed to it by Broadcaster.emit into
+// pipelined PUBLISHes, flushed each time flushInterval elapses and once
+// more when the publisher is closed.
+type asyncPublisher struct {
+	emitter  *Emitter
+	queue    chan batchItem
+	overflow AsyncOverflowPolicy
+	interval time.Duration
+	done     chan struct{}
+	stopped  chan struct{}
+}
+
+func newAsyncPublisher(emitter *Emitter, bufSize int, interval time.Duration, overflow AsyncOverflowPolicy) *asyncPublisher {
+	if overflow == "" {
+		overflow = AsyncOverflowBlock
+	}
+	if interval <= 0 {
+		interval = 100 * time.Millisecond
+	}
+
+	ap := &asyncPublisher{
+		emitter:  emitter,
+		queue:    make(chan batchItem, bufSize),
+		overflow: overflow,
+		interval: interval,
+		done:     make(chan struct{}),
+		stopped:  make(chan struct{}),
+	}
+	go ap.run()
+	return ap
+}
+
+func (ap *asyncPublisher) enqueue(item batchItem) {
+	if ap.overflow == AsyncOverflowDropOldest {
+		select {
+		case ap.queue <- item:
+		default:
+			select {
+			case <-ap.queue:
+			default:
+			}
+			select {
+			case ap.queue <- item:
+			default:
+			}
+		}
+		return
+	}
+
+	select {
+	case ap.queue <- item:
+	case <-ap.done:
+	}
+}
+
+func (ap *asyncPublisher) run() {
+	defer close(ap.stopped)
+
+	ticker := time.NewTicker(ap.interval)
+	defer ticker.Stop()
+
+	var pending []batchItem
+	flush := func() {
+		if len(pending) == 0 {
+			return
+		}
+		_ = ap.emitter.publishPipeline(context.Background(), pending)
+		pending = pending[:0]
+	}
+
+	for {
+		select {
+		case item := <-ap.queue:
+			pending = append(pending, item)
+		case <-ticker.C:
+			flush()
+		case <-ap.done:
+			// Drain whatever is still sitting in the channel before
+			// flushing and returning, so Close's "flushes any packets
+			// still buffered" promise holds even when done fires while
+			// the channel still has unread items.
+			for {
+				select {
+				case item := <-ap.queue:
+					pending = append(pending, item)
+					continue
+				default:
+				}
+				break
+			}
+			flush()
+			return
+		}
+	}
+}
+
+// Close stops the background flush loop after draining any pending
+// packets.
+func (ap *asyncPublisher) Close() {
+	close(ap.done)
+	<-ap.stopped
+}
@@ -0,0 +1,93 @@
+package SocketIO
+
+import (
+	"context"
+	"sync"
+)
+
+// batchItem is one buffered PUBLISH, queued either by a *Batch or by the
+// async publisher until it's flushed through a Redis pipeline.
+type batchItem struct {
+	channel string
+	payload []byte
+}
+
+// Batch buffers packets built through its In/To/Of/Except/Emit chain
+// and flushes them as a single Redis pipeline on Flush or Close,
+// trading one round trip per event for one round trip per batch.
+type Batch struct {
+	emitter *Emitter
+
+	mu      sync.Mutex
+	pending []batchItem
+}
+
+// Batch starts a new *Batch sharing the emitter's connection and
+// serializer. Nothing is published until Flush or Close is called.
+func (emitter *Emitter) Batch() *Batch {
+	return &Batch{emitter: emitter}
+}
+
+func (bt *Batch) broadcaster() *Broadcaster {
+	return &Broadcaster{emitter: bt.emitter, flags: map[string]interface{}{}, batch: bt}
+}
+
+func (bt *Batch) Join() *Broadcaster                 { return bt.broadcaster().Join() }
+func (bt *Batch) Volatile() *Broadcaster             { return bt.broadcaster().Volatile() }
+func (bt *Batch) Broadcast() *Broadcaster            { return bt.broadcaster().Broadcast() }
+func (bt *Batch) Compress(compress bool) *Broadcaster { return bt.broadcaster().Compress(compress) }
+func (bt *Batch) In(room string) *Broadcaster         { return bt.broadcaster().In(room) }
+func (bt *Batch) To(room string) *Broadcaster         { return bt.broadcaster().To(room) }
+func (bt *Batch) Of(namespace string) *Broadcaster    { return bt.broadcaster().Of(namespace) }
+func (bt *Batch) Except(room string) *Broadcaster     { return bt.broadcaster().Except(room) }
+
+func (bt *Batch) Emit(event string, data ...interface{}) (*Broadcaster, error) {
+	return bt.broadcaster().Emit(event, data...)
+}
+
+func (bt *Batch) EmitBinary(event string, data ...interface{}) (*Broadcaster, error) {
+	return bt.broadcaster().EmitBinary(event, data...)
+}
+
+func (bt *Batch) add(item batchItem) {
+	bt.mu.Lock()
+	bt.pending = append(bt.pending, item)
+	bt.mu.Unlock()
+}
+
+// Flush publishes every buffered packet in a single pipeline and clears
+// the batch. It's safe to keep using the Batch after Flush.
+func (bt *Batch) Flush() error {
+	bt.mu.Lock()
+	items := bt.pending
+	bt.pending = nil
+	bt.mu.Unlock()
+
+	return bt.emitter.publishPipeline(context.Background(), items)
+}
+
+// Close flushes any remaining buffered packets. A Batch has no other
+// resources to release; Close exists so `defer batch.Close()` reads
+// naturally next to Flush.
+func (bt *Batch) Close() error {
+	return bt.Flush()
+}
+
+// publishPipeline PUBLISHes (or SPUBLISHes, in sharded Cluster mode)
+// every item in a single Redis pipeline.
+func (e *Emitter) publishPipeline(ctx context.Context, items []batchItem) error {
+	if len(items) == 0 {
+		return nil
+	}
+
+	pipe := e.client.Pipeline()
+	for _, item := range items {
+		if e.clusterSharded {
+			pipe.SPublish(ctx, item.channel, item.payload)
+		} else {
+			pipe.Publish(ctx, item.channel, item.payload)
+		}
+	}
+	_, err := pipe.Exec(ctx)
+	return err
+}
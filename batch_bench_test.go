@@ -0,0 +1,79 @@
+package SocketIO
+
+import (
+	"os"
+	"testing"
+	"time"
+)
+
+// benchmarkEmitter connects to REDIS_ADDR, skipping the benchmark when
+// it isn't set — these benchmarks measure real round trips, so they
+// need an actual Redis instance to produce meaningful ops/sec numbers.
+func benchmarkEmitter(b *testing.B, opts EmitterOpts) *Emitter {
+	addr := os.Getenv("REDIS_ADDR")
+	if addr == "" {
+		b.Skip("set REDIS_ADDR to a reachable redis instance to run this benchmark")
+	}
+	opts.Addr = addr
+
+	emitter, err := NewEmitter(&opts)
+	if err != nil {
+		b.Fatalf("NewEmitter: %v", err)
+	}
+	return emitter
+}
+
+// BenchmarkEmitInline measures the original one-PUBLISH-per-call path.
+func BenchmarkEmitInline(b *testing.B) {
+	emitter := benchmarkEmitter(b, EmitterOpts{})
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if _, err := emitter.Emit("bench", i); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+// BenchmarkEmitBatch measures the pipelined Batch path, flushing every
+// batchSize events in a single PUBLISH pipeline instead of one PUBLISH
+// per event.
+func BenchmarkEmitBatch(b *testing.B) {
+	emitter := benchmarkEmitter(b, EmitterOpts{})
+	const batchSize = 100
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i += batchSize {
+		batch := emitter.Batch()
+		n := batchSize
+		if i+n > b.N {
+			n = b.N - i
+		}
+		for j := 0; j < n; j++ {
+			if _, err := batch.Emit("bench", i+j); err != nil {
+				b.Fatal(err)
+			}
+		}
+		if err := batch.Flush(); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+// BenchmarkEmitAsync measures the async buffered publisher path, where
+// Emit hands packets to a background goroutine that coalesces them into
+// pipelined PUBLISHes.
+func BenchmarkEmitAsync(b *testing.B) {
+	emitter := benchmarkEmitter(b, EmitterOpts{
+		AsyncBufferSize:    1024,
+		AsyncFlushInterval: 10 * time.Millisecond,
+	})
+	defer emitter.Close()
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if _, err := emitter.Emit("bench", i); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
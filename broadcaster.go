@@ -0,0 +1,243 @@
+package SocketIO
+
+import (
+	"context"
+	"fmt"
+
+	"google.golang.org/protobuf/proto"
+)
+
+// Broadcaster carries the per-call rooms/flags state for one emission
+// chain. Every builder method (In, To, Of, Except, Broadcast, Volatile,
+// Compress) returns a new *Broadcaster with its own copy of that state
+// (In/To return the receiver itself when it already has the room), and
+// no Broadcaster is changed after it is built, so e.g. e.To("a").Emit(...) and e.To("b").Emit(...) running on
+// different goroutines never see each other's rooms or flags.
+type Broadcaster struct {
+	emitter *Emitter
+	rooms   []string
+	flags   map[string]interface{}
+	// batch is non-nil when this Broadcaster was built via Batch()/
+	// Batch.To()/etc, in which case Emit buffers instead of publishing.
+	batch *Batch
+}
+
+// clone returns a *Broadcaster with its own copies of rooms and flags,
+// so mutating the copy never affects b or any other Broadcaster derived
+// from it.
+func (b *Broadcaster) clone() *Broadcaster {
+	rooms := make([]string, len(b.rooms))
+	copy(rooms, b.rooms)
+
+	flags := make(map[string]interface{}, len(b.flags))
+	for k, v := range b.flags {
+		flags[k] = v
+	}
+
+	return &Broadcaster{emitter: b.emitter, rooms: rooms, flags: flags, batch: b.batch}
+}
+
+func (b *Broadcaster) Join() *Broadcaster {
+	nb := b.clone()
+	nb.flags["join"] = true
+	return nb
+}
+
+func (b *Broadcaster) Volatile() *Broadcaster {
+	nb := b.clone()
+	nb.flags["volatile"] = true
+	return nb
+}
+
+func (b *Broadcaster) Broadcast() *Broadcaster {
+	nb := b.clone()
+	nb.flags["broadcast"] = true
+	return nb
+}
+
+// Compress toggles compression of the outgoing packet.
+func (b *Broadcaster) Compress(compress bool) *Broadcaster {
+	nb := b.clone()
+	nb.flags["compress"] = compress
+	return nb
+}
+
+/**
+ * Limit emission to a certain `room`.
+ *
+ * @param {String} room
+ */
+func (b *Broadcaster) In(room string) *Broadcaster {
+	for _, r := range b.rooms {
+		if r == room {
+			return b
+		}
+	}
+	nb := b.clone()
+	nb.rooms = append(nb.rooms, room)
+	return nb
+}
+
+func (b *Broadcaster) To(room string) *Broadcaster {
+	return b.In(room)
+}
+
+/**
+ * Limit emission to certain `namespace`.
+ *
+ * @param {String} namespace
+ */
+func (b *Broadcaster) Of(namespace string) *Broadcaster {
+	nb := b.clone()
+	nb.flags["nsp"] = namespace
+	return nb
+}
+
+/**
+ * Exclude a certain `room` from the emission/request.
+ *
+ * @param {String} room
+ */
+func (b *Broadcaster) Except(room string) *Broadcaster {
+	nb := b.clone()
+	except, _ := nb.flags["except"].([]string)
+	exceptCopy := make([]string, len(except), len(except)+1)
+	copy(exceptCopy, except)
+	nb.flags["except"] = append(exceptCopy, room)
+	return nb
+}
+
+// send the packet by string, json, etc
+// Usage:
+// Emit("event name", "data")
+func (b *Broadcaster) Emit(event string, data ...interface{}) (*Broadcaster, error) {
+	return b.EmitContext(context.Background(), event, data...)
+}
+
+// EmitContext behaves like Emit but threads ctx through to the
+// underlying PUBLISH, so callers can enforce deadlines and cancellation.
+// When b belongs to a Batch or the emitter runs in async mode, the
+// packet is only buffered and ctx is not used.
+func (b *Broadcaster) EmitContext(ctx context.Context, event string, data ...interface{}) (*Broadcaster, error) {
+	data, err := marshalProtoData(data)
+	if err != nil {
+		return nil, err
+	}
+
+	d := []interface{}{event}
+	d = append(d, data...)
+	eventType := EVENT
+	if HasBinary(data...) {
+		eventType = BINARY_EVENT
+	}
+	packet := map[string]interface{}{
+		"type": eventType,
+		"data": d,
+	}
+	return b, b.emit(ctx, packet)
+}
+
+// send the packet by binary
+// Usage:
+// EmitBinary("event name", []byte{0x01, 0x02, 0x03})
+func (b *Broadcaster) EmitBinary(event string, data ...interface{}) (*Broadcaster, error) {
+	data, err := marshalProtoData(data)
+	if err != nil {
+		return nil, err
+	}
+
+	d := []interface{}{event}
+	d = append(d, data...)
+	packet := map[string]interface{}{
+		"type": BINARY_EVENT,
+		"data": d,
+	}
+	return b, b.emit(context.Background(), packet)
+}
+
+// marshalProtoData replaces any proto.Message values in data with their
+// protobuf-encoded bytes via ProtobufSerializer, so callers with
+// generated .proto event types can pass them straight to Emit. Values
+// that aren't a proto.Message pass through unchanged, and this runs
+// regardless of which envelope Serializer the Emitter is configured
+// with (the envelope encoding and the event data encoding are separate
+// concerns — see protobufSerializer's doc comment).
+func marshalProtoData(data []interface{}) ([]interface{}, error) {
+	var out []interface{}
+	for i, v := range data {
+		msg, ok := v.(proto.Message)
+		if !ok {
+			continue
+		}
+		if out == nil {
+			out = append([]interface{}(nil), data...)
+		}
+		encoded, err := ProtobufSerializer.Encode(msg)
+		if err != nil {
+			return nil, fmt.Errorf("socketio: failed to encode proto payload at index %d: %w", i, err)
+		}
+		out[i] = encoded
+	}
+	if out == nil {
+		return data, nil
+	}
+	return out, nil
+}
+
+// emit serializes packet together with b's frozen rooms/flags and
+// publishes it, or buffers it in b's Batch or the emitter's async
+// publisher when one is set. It never mutates b, so b can be reused or shared freely
+// after the call.
+func (b *Broadcaster) emit(ctx context.Context, packet map[string]interface{}) error {
+	if nsp, ok := b.flags["nsp"]; ok {
+		packet["nsp"] = nsp
+	}
+
+	payload, err := b.emitter.serializer.Encode(b.envelope(packet))
+	if err != nil {
+		return err
+	}
+
+	item := batchItem{channel: b.channel(), payload: payload}
+	switch {
+	case b.batch != nil:
+		b.batch.add(item)
+	case b.emitter.async != nil:
+		b.emitter.async.enqueue(item)
+	default:
+		b.emitter.publish(ctx, item.channel, item.payload)
+	}
+	return nil
+}
+
+// envelope builds the [uid, packet, opts] triple published on the
+// broadcast channel. opts.except is lifted out of flags to sit next to
+// opts.rooms, the shape the socket.io-redis adapter expects — it reads
+// BroadcastOptions.except as a sibling of rooms, not as a nested flag.
+func (b *Broadcaster) envelope(packet map[string]interface{}) []interface{} {
+	flags := make(map[string]interface{}, len(b.flags))
+	for k, v := range b.flags {
+		if k == "except" || k == "nsp" {
+			continue
+		}
+		flags[k] = v
+	}
+
+	opts := map[string]interface{}{
+		"rooms": b.rooms,
+		"flags": flags,
+	}
+	if except, ok := b.flags["except"].([]string); ok {
+		opts["except"] = except
+	}
+
+	return []interface{}{UID, packet, opts}
+}
+
+// channel returns the redis channel this broadcaster's rooms publish to.
+func (b *Broadcaster) channel() string {
+	if len(b.rooms) == 1 {
+		return fmt.Sprintf("%s%s#", b.emitter.broadcastChannel, b.rooms[0])
+	}
+	return b.emitter.broadcastChannel
+}
@@ -0,0 +1,170 @@
+package SocketIO
+
+import (
+	"bytes"
+	"fmt"
+	"sync"
+	"testing"
+
+	"github.com/vmihailenco/msgpack"
+)
+
+func testEmitter() *Emitter {
+	return &Emitter{
+		serializer: MsgpackSerializer,
+		BroadcastOpts: &BroadcastOpts{
+			broadcastChannel: "socket.io#/#",
+			requestChannel:   "socket.io-request#/#",
+			responseChannel:  "socket.io-response#/#",
+		},
+	}
+}
+
+func TestBroadcasterChannelForRooms(t *testing.T) {
+	e := testEmitter()
+
+	if got, want := e.broadcaster().channel(), "socket.io#/#"; got != want {
+		t.Errorf("channel() with no rooms = %q, want %q", got, want)
+	}
+
+	if got, want := e.In("room-1").channel(), "socket.io#/#room-1#"; got != want {
+		t.Errorf("channel() with one room = %q, want %q", got, want)
+	}
+}
+
+// TestBroadcasterEnvelopeLiftsExceptToOpts guards against except being
+// silently dropped into the nested flags map, where a socket.io-redis
+// adapter node won't find it: opts.except must be a sibling of
+// opts.rooms, not opts.flags.except.
+func TestBroadcasterEnvelopeLiftsExceptToOpts(t *testing.T) {
+	e := testEmitter()
+	b := e.To("room-1").Except("room-2").Volatile()
+
+	pack := b.envelope(map[string]interface{}{"type": EVENT, "data": []interface{}{"event"}})
+	if len(pack) != 3 {
+		t.Fatalf("envelope() returned %d elements, want 3", len(pack))
+	}
+
+	opts, ok := pack[2].(map[string]interface{})
+	if !ok {
+		t.Fatalf("pack[2] = %#v, want map[string]interface{}", pack[2])
+	}
+
+	except, ok := opts["except"].([]string)
+	if !ok || len(except) != 1 || except[0] != "room-2" {
+		t.Errorf("opts[\"except\"] = %#v, want [\"room-2\"]", opts["except"])
+	}
+
+	flags, ok := opts["flags"].(map[string]interface{})
+	if !ok {
+		t.Fatalf("opts[\"flags\"] = %#v, want map[string]interface{}", opts["flags"])
+	}
+	if _, present := flags["except"]; present {
+		t.Errorf("flags still contains \"except\": %#v, want it lifted into opts", flags)
+	}
+	if _, present := flags["volatile"]; !present {
+		t.Errorf("flags missing \"volatile\": %#v", flags)
+	}
+}
+
+// TestBroadcasterConcurrentRoomsDoNotLeak fans out concurrent builder
+// chains over a single shared *Emitter and asserts each goroutine's
+// Broadcaster only ever sees the room it asked for. Run with -race: a
+// shared-state regression (e.g. rooms/flags living on *Emitter instead
+// of a fresh *Broadcaster per chain) shows up either as a race or as a
+// goroutine observing another goroutine's room.
+func TestBroadcasterConcurrentRoomsDoNotLeak(t *testing.T) {
+	e := testEmitter()
+
+	const goroutines = 2000
+	var wg sync.WaitGroup
+	errs := make(chan string, goroutines)
+
+	for i := 0; i < goroutines; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			room := fmt.Sprintf("room-%d", i)
+			b := e.To(room).Volatile()
+
+			if len(b.rooms) != 1 || b.rooms[0] != room {
+				errs <- fmt.Sprintf("goroutine %d: rooms = %v, want [%s]", i, b.rooms, room)
+				return
+			}
+			if want := fmt.Sprintf("%s%s#", e.broadcastChannel, room); b.channel() != want {
+				errs <- fmt.Sprintf("goroutine %d: channel = %q, want %q", i, b.channel(), want)
+			}
+		}(i)
+	}
+
+	wg.Wait()
+	close(errs)
+	for msg := range errs {
+		t.Error(msg)
+	}
+}
+
+// TestBroadcasterConcurrentEmitPublishesToCorrectChannel exercises the
+// real Emit -> emit -> Emitter.publish -> client.Publish path (not just
+// the in-memory rooms/channel() bookkeeping): it fans Emit calls for
+// distinct rooms out across goroutines against a fakePublishClient and
+// decodes each recorded payload to confirm the PUBLISH landed on the
+// channel matching the room actually encoded in that packet.
+func TestBroadcasterConcurrentEmitPublishesToCorrectChannel(t *testing.T) {
+	fake := &fakePublishClient{}
+	e := testEmitter()
+	e.client = fake
+
+	const goroutines = 500
+	var wg sync.WaitGroup
+	errs := make(chan string, goroutines)
+
+	for i := 0; i < goroutines; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			room := fmt.Sprintf("room-%d", i)
+			if _, err := e.To(room).Emit("event", i); err != nil {
+				errs <- fmt.Sprintf("goroutine %d: Emit: %v", i, err)
+			}
+		}(i)
+	}
+
+	wg.Wait()
+	close(errs)
+	for msg := range errs {
+		t.Error(msg)
+	}
+
+	messages := fake.snapshot()
+	if len(messages) != goroutines {
+		t.Fatalf("got %d published messages, want %d", len(messages), goroutines)
+	}
+
+	for _, m := range messages {
+		var pack []interface{}
+		if err := msgpack.NewDecoder(bytes.NewReader(m.payload)).Decode(&pack); err != nil {
+			t.Fatalf("decode payload for channel %q: %v", m.channel, err)
+		}
+		if len(pack) != 3 {
+			t.Fatalf("channel %q: packet has %d elements, want 3", m.channel, len(pack))
+		}
+
+		opts, ok := pack[2].(map[string]interface{})
+		if !ok {
+			t.Fatalf("channel %q: pack[2] = %#v, want map[string]interface{}", m.channel, pack[2])
+		}
+		rooms, ok := opts["rooms"].([]interface{})
+		if !ok || len(rooms) != 1 {
+			t.Fatalf("channel %q: opts[\"rooms\"] = %#v, want a single-element slice", m.channel, opts["rooms"])
+		}
+		room, ok := rooms[0].(string)
+		if !ok {
+			t.Fatalf("channel %q: rooms[0] = %#v, want string", m.channel, rooms[0])
+		}
+
+		if want := fmt.Sprintf("%s%s#", e.broadcastChannel, room); m.channel != want {
+			t.Errorf("room %q was published on channel %q, want %q", room, m.channel, want)
+		}
+	}
+}
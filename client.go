@@ -0,0 +1,52 @@
+package SocketIO
+
+import (
+	"strconv"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// buildRedisClient builds a go-redis UniversalClient from opts, picking
+// single-node, Sentinel, or Cluster mode depending on which address
+// fields are set: ClusterAddrs wins over SentinelAddrs, which wins over
+// Host/Port/Addr.
+func buildRedisClient(opts *EmitterOpts) redis.UniversalClient {
+	uopts := &redis.UniversalOptions{
+		Username:     opts.Username,
+		Password:     opts.Password,
+		DB:           opts.DB,
+		TLSConfig:    opts.TLSConfig,
+		DialTimeout:  opts.DialTimeout,
+		ReadTimeout:  opts.ReadTimeout,
+		WriteTimeout: opts.WriteTimeout,
+	}
+
+	switch {
+	case len(opts.ClusterAddrs) > 0:
+		// NewUniversalClient only picks a *redis.ClusterClient when
+		// len(Addrs) > 1, so the ClusterClient is built directly here to
+		// keep Cluster routing for a single seed node as well.
+		uopts.Addrs = opts.ClusterAddrs
+		return redis.NewClusterClient(uopts.Cluster())
+	case len(opts.SentinelAddrs) > 0:
+		uopts.Addrs = opts.SentinelAddrs
+		uopts.MasterName = opts.MasterName
+	default:
+		uopts.Addrs = []string{resolveAddr(opts)}
+	}
+
+	return redis.NewUniversalClient(uopts)
+}
+
+// resolveAddr mirrors the addr-resolution rules NewEmitter has always
+// used for single-node connections: Addr, then Host:Port, then a
+// localhost default.
+func resolveAddr(opts *EmitterOpts) string {
+	if opts.Addr != "" {
+		return opts.Addr
+	}
+	if opts.Host != "" && opts.Port > 0 {
+		return opts.Host + ":" + strconv.Itoa(opts.Port)
+	}
+	return "localhost:6379"
+}
@@ -2,23 +2,19 @@ package SocketIO
 
 import (
 	"bytes"
+	"context"
+	"crypto/tls"
 	"fmt"
 	"os"
-	"strconv"
 	"time"
 
-	"github.com/garyburd/redigo/redis"
-	"github.com/sirupsen/logrus"
-	"github.com/vmihailenco/msgpack"
+	"github.com/redis/go-redis/v9"
 )
 
 const (
 	UID          = "emitter"
 	EVENT        = 2
 	BINARY_EVENT = 5
-
-	redisPoolMaxIdle   = 80
-	redisPoolMaxActive = 10000 // max number of connections
 )
 
 type EmitterOpts struct {
@@ -32,58 +28,86 @@ type EmitterOpts struct {
 	Protocol string
 	// Address, like localhost:6379
 	Addr string
+
+	// MasterName is the Sentinel-monitored master name, e.g. "mymaster".
+	// Set together with SentinelAddrs to enable Sentinel-based failover.
+	MasterName string
+	// SentinelAddrs is the list of Sentinel addresses ("host:port") to
+	// query for the current master. Takes precedence over Host/Port/Addr.
+	SentinelAddrs []string
+
+	// ClusterAddrs is the list of Redis Cluster seed node addresses
+	// ("host:port"). When set, the emitter runs in Cluster mode and
+	// go-redis routes each PUBLISH to the shard owning the channel's
+	// hash slot.
+	ClusterAddrs []string
+	// ClusterSharded switches publish to SPUBLISH, Redis 7's
+	// slot-local sharded pub/sub command, instead of PUBLISH.
+	ClusterSharded bool
+
+	// Username and Password are passed through for Redis ACL auth.
+	Username string
+	Password string
+	// DB selects the logical database (ignored in Cluster mode).
+	DB int
+	// TLSConfig enables TLS when non-nil, as required by most managed
+	// Redis services (ElastiCache, Upstash, ...).
+	TLSConfig *tls.Config
+
+	DialTimeout  time.Duration
+	ReadTimeout  time.Duration
+	WriteTimeout time.Duration
+
+	// Serializer controls how broadcast and request-protocol packets
+	// (SocketsJoin, AllRooms, ...) are encoded/decoded. Defaults to
+	// MsgpackSerializer, matching the historical wire format. Set
+	// JSONSerializer to interoperate with a default-configured
+	// socket.io-redis JS adapter, or a custom codec registered via
+	// RegisterSerializer — it must match whatever the rest of the
+	// cluster is configured with, since requests and their replies are
+	// decoded with this same Serializer. NewEmitter rejects
+	// ProtobufSerializer here; proto.Message values passed to Emit are
+	// encoded with it automatically.
+	Serializer Serializer
+
+	// AsyncBufferSize, when greater than zero, switches Emit to async
+	// mode: packets are handed to a background goroutine that coalesces
+	// them into pipelined PUBLISHes instead of publishing inline. The
+	// value sets the size of the buffering channel.
+	AsyncBufferSize int
+	// AsyncFlushInterval bounds how long a packet can sit buffered
+	// before the async publisher flushes it. Defaults to 100ms.
+	AsyncFlushInterval time.Duration
+	// AsyncOverflow selects what happens when the async buffer is full.
+	// Defaults to AsyncOverflowBlock.
+	AsyncOverflow AsyncOverflowPolicy
 }
 
 type BroadcastOpts struct {
 	nsp              string
 	broadcastChannel string
 	requestChannel   string
+	responseChannel  string
 }
 
+// Emitter holds everything shared across every broadcast made through
+// it: the Redis connection, the channel layout, and the serializer. It
+// carries no per-call state (rooms, flags), so a single Emitter is safe
+// to share and call concurrently from multiple goroutines. Per-call
+// state lives on the *Broadcaster values returned by In/To/Of/Except/
+// Broadcast/Volatile/Compress.
 type Emitter struct {
-	Key       string
-	rooms     []string
-	flags     map[string]interface{}
-	redisPool *redis.Pool
+	Key            string
+	client         redis.UniversalClient
+	clusterSharded bool
+	serializer     Serializer
+	// async is non-nil when EmitterOpts.AsyncBufferSize > 0, switching
+	// Emit to buffer through a background pipelined publisher.
+	async *asyncPublisher
 
 	*BroadcastOpts
 }
 
-func initRedisConnPool(opts *EmitterOpts) *redis.Pool {
-	if opts.Host == "" {
-		// return err
-	}
-
-	var addr string
-	if opts.Addr != "" {
-		addr = opts.Addr
-	} else if opts.Host != "" && opts.Port > 0 {
-		addr = opts.Host + ":" + strconv.Itoa(opts.Port)
-	} else {
-		addr = "localhost:6379"
-	}
-
-	return &redis.Pool{
-		MaxIdle:   redisPoolMaxIdle,
-		MaxActive: redisPoolMaxActive,
-		Dial: func() (redis.Conn, error) {
-			c, err := redis.Dial("tcp", addr)
-			if err != nil {
-				logrus.Errorf("Failed to init redis pool: %+v", err)
-				return nil, err
-			}
-
-			// TODO: passwd check if needed
-
-			return c, nil
-		},
-		TestOnBorrow: func(c redis.Conn, t time.Time) error {
-			_, err := c.Do("PING")
-			return err
-		},
-	}
-}
-
 // Emitter constructor
 // Usage:
 // SocketIO.NewEmitter(&SocketIO.EmitterOpts{
@@ -99,29 +123,63 @@ func NewEmitter(opts *EmitterOpts) (*Emitter, error) {
 	broadcastOpts := &BroadcastOpts{
 		broadcastChannel: fmt.Sprintf("%s#%s#", opts.Key, nsp),
 		requestChannel:   fmt.Sprintf("%s-request#%s#", opts.Key, nsp),
+		responseChannel:  fmt.Sprintf("%s-response#%s#", opts.Key, nsp),
+	}
+
+	serializer := opts.Serializer
+	if serializer == nil {
+		serializer = MsgpackSerializer
+	}
+	if serializer == ProtobufSerializer {
+		return nil, fmt.Errorf("socketio: ProtobufSerializer cannot encode the broadcast envelope; use MsgpackSerializer or JSONSerializer for EmitterOpts.Serializer and pass proto.Message values to Emit, which encodes them automatically")
 	}
 
 	emitter := &Emitter{
-		Key:           opts.Key,
-		redisPool:     initRedisConnPool(opts),
-		BroadcastOpts: broadcastOpts,
+		Key:            opts.Key,
+		client:         buildRedisClient(opts),
+		clusterSharded: opts.ClusterSharded,
+		serializer:     serializer,
+		BroadcastOpts:  broadcastOpts,
 	}
+
+	if opts.AsyncBufferSize > 0 {
+		emitter.async = newAsyncPublisher(emitter, opts.AsyncBufferSize, opts.AsyncFlushInterval, opts.AsyncOverflow)
+	}
+
 	return emitter, nil
 }
 
-func (emitter *Emitter) Join() *Emitter {
-	emitter.flags["join"] = true
-	return emitter
+// Close stops the background async publisher, if one is running,
+// flushing any packets still buffered. It's a no-op when
+// EmitterOpts.AsyncBufferSize wasn't set.
+func (emitter *Emitter) Close() {
+	if emitter.async != nil {
+		emitter.async.Close()
+	}
 }
 
-func (emitter *Emitter) Volatile() *Emitter {
-	emitter.flags["volatile"] = true
-	return emitter
+// broadcaster starts a fresh, empty *Broadcaster rooted at emitter. Every
+// chain (e.g. e.To("room").Emit(...)) begins here, so concurrent chains
+// never share rooms/flags state.
+func (emitter *Emitter) broadcaster() *Broadcaster {
+	return &Broadcaster{emitter: emitter, flags: map[string]interface{}{}}
 }
 
-func (emitter *Emitter) Broadcast() *Emitter {
-	emitter.flags["broadcast"] = true
-	return emitter
+func (emitter *Emitter) Join() *Broadcaster {
+	return emitter.broadcaster().Join()
+}
+
+func (emitter *Emitter) Volatile() *Broadcaster {
+	return emitter.broadcaster().Volatile()
+}
+
+func (emitter *Emitter) Broadcast() *Broadcaster {
+	return emitter.broadcaster().Broadcast()
+}
+
+// Compress toggles compression of the outgoing packet.
+func (emitter *Emitter) Compress(compress bool) *Broadcaster {
+	return emitter.broadcaster().Compress(compress)
 }
 
 /**
@@ -129,18 +187,12 @@ func (emitter *Emitter) Broadcast() *Emitter {
  *
  * @param {String} room
  */
-func (emitter *Emitter) In(room string) *Emitter {
-	for _, r := range emitter.rooms {
-		if r == room {
-			return emitter
-		}
-	}
-	emitter.rooms = append(emitter.rooms, room)
-	return emitter
+func (emitter *Emitter) In(room string) *Broadcaster {
+	return emitter.broadcaster().In(room)
 }
 
-func (emitter *Emitter) To(room string) *Emitter {
-	return emitter.In(room)
+func (emitter *Emitter) To(room string) *Broadcaster {
+	return emitter.broadcaster().To(room)
 }
 
 /**
@@ -148,39 +200,38 @@ func (emitter *Emitter) To(room string) *Emitter {
  *
  * @param {String} namespace
  */
-func (emitter *Emitter) Of(namespace string) *Emitter {
-	emitter.flags["nsp"] = namespace
-	return emitter
+func (emitter *Emitter) Of(namespace string) *Broadcaster {
+	return emitter.broadcaster().Of(namespace)
+}
+
+/**
+ * Exclude a certain `room` from the emission/request.
+ *
+ * @param {String} room
+ */
+func (emitter *Emitter) Except(room string) *Broadcaster {
+	return emitter.broadcaster().Except(room)
 }
 
 // send the packet by string, json, etc
 // Usage:
 // Emit("event name", "data")
-func (emitter *Emitter) Emit(event string, data ...interface{}) (*Emitter, error) {
-	d := []interface{}{event}
-	d = append(d, data...)
-	eventType := EVENT
-	if HasBinary(data...) {
-		eventType = BINARY_EVENT
-	}
-	packet := map[string]interface{}{
-		"type": eventType,
-		"data": d,
-	}
-	return emitter.emit(packet)
+func (emitter *Emitter) Emit(event string, data ...interface{}) (*Broadcaster, error) {
+	return emitter.broadcaster().Emit(event, data...)
+}
+
+// EmitContext behaves like Emit but threads ctx through to the
+// underlying PUBLISH, so callers can enforce deadlines and cancellation.
+// In async mode the packet is only queued and ctx is not used.
+func (emitter *Emitter) EmitContext(ctx context.Context, event string, data ...interface{}) (*Broadcaster, error) {
+	return emitter.broadcaster().EmitContext(ctx, event, data...)
 }
 
 // send the packet by binary
 // Usage:
 // EmitBinary("event name", []byte{0x01, 0x02, 0x03})
-func (emitter *Emitter) EmitBinary(event string, data ...interface{}) (*Emitter, error) {
-	d := []interface{}{event}
-	d = append(d, data...)
-	packet := map[string]interface{}{
-		"type": BINARY_EVENT,
-		"data": d,
-	}
-	return emitter.emit(packet)
+func (emitter *Emitter) EmitBinary(event string, data ...interface{}) (*Broadcaster, error) {
+	return emitter.broadcaster().EmitBinary(event, data...)
 }
 
 func HasBinary(dataSlice ...interface{}) bool {
@@ -214,41 +265,14 @@ func HasBinary(dataSlice ...interface{}) bool {
 	return false
 }
 
-func (e *Emitter) publish(channel string, buf *bytes.Buffer) {
-	c := e.redisPool.Get()
-	defer c.Close()
-	_, err := c.Do("PUBLISH", channel, buf)
+func (e *Emitter) publish(ctx context.Context, channel string, payload []byte) {
+	var err error
+	if e.clusterSharded {
+		err = e.client.SPublish(ctx, channel, payload).Err()
+	} else {
+		err = e.client.Publish(ctx, channel, payload).Err()
+	}
 	if err != nil {
 		fmt.Fprintf(os.Stderr, "ERROR: %+v", err)
 	}
 }
-
-func (emitter *Emitter) emit(packet map[string]interface{}) (*Emitter, error) {
-	if emitter.flags["nsp"] != nil {
-		packet["nsp"] = emitter.flags["nsp"]
-		delete(emitter.flags, "nsp")
-	}
-	var pack []interface{} = make([]interface{}, 0)
-	pack = append(pack, UID)
-	pack = append(pack, packet)
-	pack = append(pack, map[string]interface{}{
-		"rooms": emitter.rooms,
-		"flags": emitter.flags,
-	})
-	buf := &bytes.Buffer{}
-	enc := msgpack.NewEncoder(buf)
-	error := enc.Encode(pack)
-	if error != nil {
-		return nil, error
-	}
-
-	emitter.flags = make(map[string]interface{})
-
-	channel := emitter.BroadcastOpts.broadcastChannel
-	if len(emitter.rooms) == 1 {
-		channel = fmt.Sprintf("%s%s#", emitter.broadcastChannel, emitter.rooms[0])
-	}
-
-	emitter.publish(channel, buf)
-	return emitter, nil
-}
@@ -0,0 +1,53 @@
+package SocketIO
+
+import (
+	"context"
+	"sync"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// publishedMessage records one Publish/SPublish call observed by
+// fakePublishClient.
+type publishedMessage struct {
+	channel string
+	payload []byte
+}
+
+// fakePublishClient is a minimal redis.UniversalClient that only
+// implements Publish/SPublish, recording every call instead of talking
+// to a real Redis server. Embedding the (nil) interface satisfies every
+// other method redis.UniversalClient requires; none of them are called
+// by the code under test here.
+type fakePublishClient struct {
+	redis.UniversalClient
+
+	mu       sync.Mutex
+	messages []publishedMessage
+}
+
+func (f *fakePublishClient) Publish(ctx context.Context, channel string, message interface{}) *redis.IntCmd {
+	return f.record(ctx, channel, message)
+}
+
+func (f *fakePublishClient) SPublish(ctx context.Context, channel string, message interface{}) *redis.IntCmd {
+	return f.record(ctx, channel, message)
+}
+
+func (f *fakePublishClient) record(ctx context.Context, channel string, message interface{}) *redis.IntCmd {
+	payload, _ := message.([]byte)
+
+	f.mu.Lock()
+	f.messages = append(f.messages, publishedMessage{channel: channel, payload: payload})
+	f.mu.Unlock()
+
+	return redis.NewIntCmd(ctx)
+}
+
+func (f *fakePublishClient) snapshot() []publishedMessage {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	out := make([]publishedMessage, len(f.messages))
+	copy(out, f.messages)
+	return out
+}
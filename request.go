@@ -0,0 +1,222 @@
+package SocketIO
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// Request types mirror the numeric codes used by the socket.io-redis
+// adapter's request protocol, so requests published here are understood
+// by any node running that adapter.
+const (
+	requestTypeSockets          = 0
+	requestTypeAllRooms         = 1
+	requestTypeRemoteJoin       = 2
+	requestTypeRemoteLeave      = 3
+	requestTypeRemoteDisconnect = 4
+	requestTypeServerSideEmit   = 6
+)
+
+// SocketsJoin makes every socket join rooms on every node in the
+// cluster, with no room or namespace filter; the Broadcaster returned by
+// In()/Of()/Except() carries one.
+func (emitter *Emitter) SocketsJoin(rooms ...string) error {
+	return emitter.broadcaster().SocketsJoin(rooms...)
+}
+
+// SocketsLeave makes every socket leave rooms on every node in the
+// cluster, with no room or namespace filter; the Broadcaster returned by
+// In()/Of()/Except() carries one.
+func (emitter *Emitter) SocketsLeave(rooms ...string) error {
+	return emitter.broadcaster().SocketsLeave(rooms...)
+}
+
+// DisconnectSockets force-disconnects every socket across every node in
+// the cluster, with no room or namespace filter; the Broadcaster returned
+// by In()/Of()/Except() carries one.
+func (emitter *Emitter) DisconnectSockets(close bool) error {
+	return emitter.broadcaster().DisconnectSockets(close)
+}
+
+// ServerSideEmit delivers event to server-side listeners registered by
+// other nodes in the cluster, rather than to client sockets.
+func (emitter *Emitter) ServerSideEmit(event string, data ...interface{}) error {
+	return emitter.broadcaster().ServerSideEmit(event, data...)
+}
+
+// AllRooms fan-outs a request for the set of rooms known across the
+// cluster and aggregates the replies published by each node on the
+// response channel. ctx should carry a deadline: collection runs until
+// ctx is done, since there's no way to know how many nodes will reply.
+func (emitter *Emitter) AllRooms(ctx context.Context) ([]string, error) {
+	return emitter.broadcaster().AllRooms(ctx)
+}
+
+// AllSockets behaves like AllRooms but aggregates matching socket ids
+// instead of room names.
+func (emitter *Emitter) AllSockets(ctx context.Context) ([]string, error) {
+	return emitter.broadcaster().AllSockets(ctx)
+}
+
+// SocketsJoin makes every socket matching b's In()/Of()/Except() filter
+// join rooms on every node in the cluster.
+func (b *Broadcaster) SocketsJoin(rooms ...string) error {
+	_, err := b.request(context.Background(), requestTypeRemoteJoin, map[string]interface{}{
+		"rooms": rooms,
+	})
+	return err
+}
+
+// SocketsLeave makes every socket matching b's In()/Of()/Except() filter
+// leave rooms on every node in the cluster.
+func (b *Broadcaster) SocketsLeave(rooms ...string) error {
+	_, err := b.request(context.Background(), requestTypeRemoteLeave, map[string]interface{}{
+		"rooms": rooms,
+	})
+	return err
+}
+
+// DisconnectSockets force-disconnects every socket matching b's
+// In()/Of()/Except() filter, across every node in the cluster. close
+// controls whether the underlying connection is closed or just the
+// socket.io session.
+func (b *Broadcaster) DisconnectSockets(close bool) error {
+	_, err := b.request(context.Background(), requestTypeRemoteDisconnect, map[string]interface{}{
+		"close": close,
+	})
+	return err
+}
+
+// ServerSideEmit delivers event to server-side listeners registered by
+// other nodes in the cluster, rather than to client sockets.
+func (b *Broadcaster) ServerSideEmit(event string, data ...interface{}) error {
+	payload := append([]interface{}{event}, data...)
+	_, err := b.request(context.Background(), requestTypeServerSideEmit, map[string]interface{}{
+		"data": payload,
+	})
+	return err
+}
+
+// AllRooms fan-outs a request for the set of rooms known across the
+// cluster and aggregates the replies published by each node on the
+// response channel. ctx should carry a deadline: collection runs until
+// ctx is done, since there's no way to know how many nodes will reply.
+func (b *Broadcaster) AllRooms(ctx context.Context) ([]string, error) {
+	return b.fanoutStrings(ctx, requestTypeAllRooms, "rooms")
+}
+
+// AllSockets behaves like AllRooms but aggregates matching socket ids
+// instead of room names.
+func (b *Broadcaster) AllSockets(ctx context.Context) ([]string, error) {
+	return b.fanoutStrings(ctx, requestTypeSockets, "ids")
+}
+
+// fanoutStrings publishes a request of reqType, subscribes to the
+// response channel, and collects the string slice found under field in
+// every reply tagged with the request's id until ctx is done.
+func (b *Broadcaster) fanoutStrings(ctx context.Context, reqType int, field string) ([]string, error) {
+	sub := b.emitter.client.Subscribe(ctx, b.emitter.responseChannel)
+	defer sub.Close()
+
+	requestId, err := b.request(ctx, reqType, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	return collectStrings(ctx, sub.Channel(), b.emitter.serializer, requestId, field)
+}
+
+// collectStrings reads replies off ch until it's closed or ctx is done,
+// decoding each with serializer and collecting the deduplicated string
+// slice found under field in every reply whose requestId matches. It's
+// split out from fanoutStrings so the aggregation logic can be tested
+// against a plain channel of *redis.Message, without a live subscription.
+func collectStrings(ctx context.Context, ch <-chan *redis.Message, serializer Serializer, requestId, field string) ([]string, error) {
+	seen := make(map[string]struct{})
+	for {
+		select {
+		case <-ctx.Done():
+			return stringSetToSlice(seen), ctx.Err()
+		case msg, ok := <-ch:
+			if !ok {
+				return stringSetToSlice(seen), nil
+			}
+			var resp map[string]interface{}
+			if err := serializer.Decode([]byte(msg.Payload), &resp); err != nil {
+				continue
+			}
+			if resp["requestId"] != requestId {
+				continue
+			}
+			for _, item := range toInterfaceSlice(resp[field]) {
+				if s, ok := item.(string); ok {
+					seen[s] = struct{}{}
+				}
+			}
+		}
+	}
+}
+
+// request builds and publishes a socket.io-redis request packet from b's
+// frozen rooms/except filter and returns the generated request id. It
+// never mutates b.
+func (b *Broadcaster) request(ctx context.Context, reqType int, extra map[string]interface{}) (string, error) {
+	requestId, err := newRequestID()
+	if err != nil {
+		return "", err
+	}
+
+	opts := map[string]interface{}{
+		"rooms": b.rooms,
+	}
+	if except, ok := b.flags["except"].([]string); ok {
+		opts["except"] = except
+	}
+
+	req := map[string]interface{}{
+		"uid":       UID,
+		"requestId": requestId,
+		"type":      reqType,
+		"opts":      opts,
+	}
+	if nsp, ok := b.flags["nsp"]; ok {
+		req["nsp"] = nsp
+	}
+	for k, v := range extra {
+		req[k] = v
+	}
+
+	payload, err := b.emitter.serializer.Encode(req)
+	if err != nil {
+		return "", err
+	}
+
+	if err := b.emitter.client.Publish(ctx, b.emitter.requestChannel, payload).Err(); err != nil {
+		return "", err
+	}
+	return requestId, nil
+}
+
+func newRequestID() (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
+
+func stringSetToSlice(set map[string]struct{}) []string {
+	out := make([]string, 0, len(set))
+	for s := range set {
+		out = append(out, s)
+	}
+	return out
+}
+
+func toInterfaceSlice(v interface{}) []interface{} {
+	items, _ := v.([]interface{})
+	return items
+}
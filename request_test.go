@@ -0,0 +1,146 @@
+package SocketIO
+
+import (
+	"context"
+	"reflect"
+	"sort"
+	"testing"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+func TestBroadcasterSocketsJoinPublishesRequestShape(t *testing.T) {
+	fake := &fakePublishClient{}
+	e := testEmitter()
+	e.client = fake
+
+	if err := e.To("room-1").SocketsJoin("room-2", "room-3"); err != nil {
+		t.Fatalf("SocketsJoin: %v", err)
+	}
+
+	messages := fake.snapshot()
+	if len(messages) != 1 {
+		t.Fatalf("got %d published messages, want 1", len(messages))
+	}
+	if messages[0].channel != e.requestChannel {
+		t.Errorf("published on channel %q, want %q", messages[0].channel, e.requestChannel)
+	}
+
+	var req map[string]interface{}
+	if err := MsgpackSerializer.Decode(messages[0].payload, &req); err != nil {
+		t.Fatalf("decode request: %v", err)
+	}
+
+	if req["uid"] != UID {
+		t.Errorf("req[\"uid\"] = %v, want %q", req["uid"], UID)
+	}
+	if _, ok := req["requestId"].(string); !ok {
+		t.Errorf("req[\"requestId\"] = %#v, want a string", req["requestId"])
+	}
+	if got := requestTypeFromReply(t, req["type"]); got != requestTypeRemoteJoin {
+		t.Errorf("req[\"type\"] = %#v, want %d", req["type"], requestTypeRemoteJoin)
+	}
+
+	opts, ok := req["opts"].(map[string]interface{})
+	if !ok {
+		t.Fatalf("req[\"opts\"] = %#v, want map[string]interface{}", req["opts"])
+	}
+	rooms, ok := opts["rooms"].([]interface{})
+	if !ok || len(rooms) != 1 || rooms[0] != "room-1" {
+		t.Errorf("opts[\"rooms\"] = %#v, want [\"room-1\"]", opts["rooms"])
+	}
+
+	joinRooms, ok := req["rooms"].([]interface{})
+	if !ok || len(joinRooms) != 2 {
+		t.Fatalf("req[\"rooms\"] = %#v, want the two rooms passed to SocketsJoin", req["rooms"])
+	}
+}
+
+// TestCollectStringsDedupsAcrossReplies asserts that collectStrings
+// aggregates field values from every reply tagged with requestId,
+// deduping repeats and ignoring replies tagged with a different id.
+func TestCollectStringsDedupsAcrossReplies(t *testing.T) {
+	ch := make(chan *redis.Message, 4)
+	ch <- encodeReply(t, "req-1", "rooms", []interface{}{"a", "b"})
+	ch <- encodeReply(t, "req-1", "rooms", []interface{}{"b", "c"})
+	ch <- encodeReply(t, "other-request", "rooms", []interface{}{"z"})
+	close(ch)
+
+	got, err := collectStrings(context.Background(), ch, MsgpackSerializer, "req-1", "rooms")
+	if err != nil {
+		t.Fatalf("collectStrings: %v", err)
+	}
+
+	sort.Strings(got)
+	want := []string{"a", "b", "c"}
+	if len(got) != len(want) {
+		t.Fatalf("got %v, want %v", got, want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("got %v, want %v", got, want)
+			break
+		}
+	}
+}
+
+// TestCollectStringsStopsOnContextDone asserts collectStrings returns
+// whatever it gathered so far, along with ctx.Err(), once ctx is
+// cancelled instead of blocking forever on an open channel.
+func TestCollectStringsStopsOnContextDone(t *testing.T) {
+	ch := make(chan *redis.Message)
+	ctx, cancel := context.WithCancel(context.Background())
+
+	done := make(chan struct{})
+	var got []string
+	var err error
+	go func() {
+		got, err = collectStrings(ctx, ch, MsgpackSerializer, "req-1", "rooms")
+		close(done)
+	}()
+
+	cancel()
+
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("collectStrings did not return after ctx was cancelled")
+	}
+
+	if err != context.Canceled {
+		t.Errorf("err = %v, want context.Canceled", err)
+	}
+	if len(got) != 0 {
+		t.Errorf("got = %v, want empty", got)
+	}
+}
+
+// requestTypeFromReply converts a decoded "type" field back to an int,
+// independent of which concrete integer type the serializer chose to
+// decode it as.
+func requestTypeFromReply(t *testing.T, v interface{}) int {
+	t.Helper()
+	rv := reflect.ValueOf(v)
+	switch rv.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return int(rv.Int())
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return int(rv.Uint())
+	default:
+		t.Fatalf("req[\"type\"] = %#v, want an integer", v)
+		return 0
+	}
+}
+
+func encodeReply(t *testing.T, requestId, field string, values []interface{}) *redis.Message {
+	t.Helper()
+	payload, err := MsgpackSerializer.Encode(map[string]interface{}{
+		"requestId": requestId,
+		field:       values,
+	})
+	if err != nil {
+		t.Fatalf("encode reply: %v", err)
+	}
+	return &redis.Message{Payload: string(payload)}
+}
@@ -0,0 +1,116 @@
+package SocketIO
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"sync"
+
+	"github.com/vmihailenco/msgpack"
+	"google.golang.org/protobuf/proto"
+)
+
+// Serializer encodes and decodes the packets exchanged with the
+// socket.io-redis adapter: outgoing broadcasts and requests via Encode,
+// and incoming request-protocol replies via Decode. Encode/Decode must
+// round-trip with each other, since request.go uses the same Serializer
+// for both directions on a given Emitter.
+type Serializer interface {
+	Encode(packet interface{}) ([]byte, error)
+	Decode(data []byte, v interface{}) error
+	ContentType() string
+}
+
+type msgpackSerializer struct{}
+
+func (msgpackSerializer) Encode(packet interface{}) ([]byte, error) {
+	buf := &bytes.Buffer{}
+	if err := msgpack.NewEncoder(buf).Encode(packet); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+func (msgpackSerializer) Decode(data []byte, v interface{}) error {
+	return msgpack.Unmarshal(data, v)
+}
+
+func (msgpackSerializer) ContentType() string { return "application/msgpack" }
+
+type jsonSerializer struct{}
+
+func (jsonSerializer) Encode(packet interface{}) ([]byte, error) {
+	return json.Marshal(packet)
+}
+
+func (jsonSerializer) Decode(data []byte, v interface{}) error {
+	return json.Unmarshal(data, v)
+}
+
+func (jsonSerializer) ContentType() string { return "application/json" }
+
+// protobufSerializer encodes a single protocol buffer message. It only
+// accepts values implementing proto.Message, so unlike MsgpackSerializer
+// and JSONSerializer it cannot encode the [uid, packet, opts] broadcast
+// envelope itself — that envelope mixes strings, maps and slices with no
+// fixed schema, which protobuf can't represent. It's used to encode the
+// individual proto.Message values inside an event's data, not EmitterOpts.
+// Serializer; see the automatic proto.Message handling in marshalProtoData,
+// called from Broadcaster.EmitContext and Broadcaster.EmitBinary.
+type protobufSerializer struct{}
+
+func (protobufSerializer) Encode(packet interface{}) ([]byte, error) {
+	msg, ok := packet.(proto.Message)
+	if !ok {
+		return nil, fmt.Errorf("socketio: protobuf serializer requires a proto.Message, got %T", packet)
+	}
+	return proto.Marshal(msg)
+}
+
+func (protobufSerializer) Decode(data []byte, v interface{}) error {
+	msg, ok := v.(proto.Message)
+	if !ok {
+		return fmt.Errorf("socketio: protobuf serializer requires a proto.Message, got %T", v)
+	}
+	return proto.Unmarshal(data, msg)
+}
+
+func (protobufSerializer) ContentType() string { return "application/protobuf" }
+
+// Built-in serializers. MsgpackSerializer is the default and matches the
+// emitter's historical wire format. ProtobufSerializer is not a valid
+// EmitterOpts.Serializer — see protobufSerializer's doc comment —
+// NewEmitter rejects it there; use it for data values that are
+// themselves proto.Message, encoded automatically by Emit/EmitBinary.
+var (
+	MsgpackSerializer  Serializer = msgpackSerializer{}
+	JSONSerializer     Serializer = jsonSerializer{}
+	ProtobufSerializer Serializer = protobufSerializer{}
+)
+
+var (
+	serializerRegistryMu sync.RWMutex
+	serializerRegistry   = map[string]Serializer{
+		"msgpack":  MsgpackSerializer,
+		"json":     JSONSerializer,
+		"protobuf": ProtobufSerializer,
+	}
+)
+
+// RegisterSerializer makes a Serializer available under name so third
+// parties can plug in additional codecs (CBOR, etc).
+func RegisterSerializer(name string, s Serializer) {
+	serializerRegistryMu.Lock()
+	defer serializerRegistryMu.Unlock()
+	serializerRegistry[name] = s
+}
+
+// SerializerByName looks up a serializer previously registered via
+// RegisterSerializer, or one of the built-ins ("msgpack", "json",
+// "protobuf").
+func SerializerByName(name string) (Serializer, bool) {
+	serializerRegistryMu.RLock()
+	defer serializerRegistryMu.RUnlock()
+	s, ok := serializerRegistry[name]
+	return s, ok
+}
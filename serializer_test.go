@@ -0,0 +1,81 @@
+package SocketIO
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/wrapperspb"
+)
+
+func TestJSONSerializerEncode(t *testing.T) {
+	payload, err := JSONSerializer.Encode(map[string]interface{}{"a": 1})
+	if err != nil {
+		t.Fatalf("Encode: %v", err)
+	}
+
+	var got map[string]interface{}
+	if err := json.Unmarshal(payload, &got); err != nil {
+		t.Fatalf("round trip: %v", err)
+	}
+	if got["a"] != float64(1) {
+		t.Errorf("got[\"a\"] = %v, want 1", got["a"])
+	}
+}
+
+func TestRegisterSerializerAndLookup(t *testing.T) {
+	RegisterSerializer("test-noop", JSONSerializer)
+	defer RegisterSerializer("test-noop", nil)
+
+	s, ok := SerializerByName("test-noop")
+	if !ok || s != JSONSerializer {
+		t.Fatalf("SerializerByName(\"test-noop\") = %v, %v, want JSONSerializer, true", s, ok)
+	}
+
+	if _, ok := SerializerByName("does-not-exist"); ok {
+		t.Error("SerializerByName(\"does-not-exist\") = true, want false")
+	}
+}
+
+func TestNewEmitterRejectsProtobufAsEnvelopeSerializer(t *testing.T) {
+	_, err := NewEmitter(&EmitterOpts{Addr: "localhost:6379", Serializer: ProtobufSerializer})
+	if err == nil {
+		t.Fatal("NewEmitter with Serializer: ProtobufSerializer = nil error, want an error")
+	}
+}
+
+func TestMarshalProtoDataEncodesProtoMessages(t *testing.T) {
+	msg := wrapperspb.String("hello")
+	want, err := proto.Marshal(msg)
+	if err != nil {
+		t.Fatalf("proto.Marshal: %v", err)
+	}
+
+	out, err := marshalProtoData([]interface{}{msg, "plain-string", 42})
+	if err != nil {
+		t.Fatalf("marshalProtoData: %v", err)
+	}
+
+	got, ok := out[0].([]byte)
+	if !ok {
+		t.Fatalf("out[0] = %#v, want []byte", out[0])
+	}
+	if string(got) != string(want) {
+		t.Errorf("out[0] = %x, want %x", got, want)
+	}
+	if out[1] != "plain-string" || out[2] != 42 {
+		t.Errorf("non-proto values were altered: %#v", out[1:])
+	}
+}
+
+func TestMarshalProtoDataPassesThroughWhenNoProtoValues(t *testing.T) {
+	in := []interface{}{"a", 1, []byte{0x01}}
+	out, err := marshalProtoData(in)
+	if err != nil {
+		t.Fatalf("marshalProtoData: %v", err)
+	}
+	if !reflect.DeepEqual(out, in) {
+		t.Errorf("out = %#v, want unchanged %#v", out, in)
+	}
+}